@@ -0,0 +1,36 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+// ActResult is what a step's action (or cleanup) produced. Concrete step
+// types populate it with whatever is relevant to the action they ran;
+// RunSteps feeds Outputs into later steps' templating and into findings
+// reports. ExitCode and Stdout exist so a RetryPolicy can inspect an
+// attempt's concrete result rather than just the error it returned.
+type ActResult struct {
+	// Outputs holds the named values the step's `outputs:` block
+	// extracted from its result, keyed by output name.
+	Outputs map[string]string
+	// ExitCode is the action's process exit code, if it ran one.
+	ExitCode int
+	// Stdout is the action's captured standard output (and, for inline
+	// steps, standard error too), if it produced any.
+	Stdout string
+}
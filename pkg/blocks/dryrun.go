@@ -0,0 +1,104 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+// Executor runs a single step's action and its cleanup. TTP.RunSteps and
+// TTP.startCleanupAtStepIdx delegate to an Executor rather than invoking
+// a step's action directly, so that a simulation backend can be swapped
+// in for dry runs without either of them needing to know the difference.
+type Executor interface {
+	// ExecuteStep runs step's action and returns its result.
+	ExecuteStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error)
+	// CleanupStep runs step's cleanup action and returns its result.
+	CleanupStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error)
+}
+
+// liveExecutor is the default Executor: it simply invokes the step's own
+// Execute/Cleanup methods, i.e. today's behavior.
+type liveExecutor struct{}
+
+// ExecuteStep implements Executor for liveExecutor.
+func (liveExecutor) ExecuteStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return step.Execute(execCtx)
+}
+
+// CleanupStep implements Executor for liveExecutor.
+func (liveExecutor) CleanupStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return step.Cleanup(execCtx)
+}
+
+// dryRunExecutor is the simulation backend used when
+// TTPExecutionConfig.DryRun is set. It validates and renders each step as
+// usual, but never invokes the underlying command/file/edit/subttp
+// action, instead returning a synthetic success so later steps still see
+// plausible `outputs`.
+type dryRunExecutor struct{}
+
+// ExecuteStep implements Executor for dryRunExecutor. It validates the
+// step, renders its templated args/env against execCtx, and records what
+// would have run without actually running it.
+func (dryRunExecutor) ExecuteStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	if err := step.Validate(execCtx); err != nil {
+		return nil, fmt.Errorf("dry-run validation failed for step %q: %w", step.Name, err)
+	}
+
+	rendered, err := step.RenderTemplated(execCtx)
+	if err != nil {
+		return nil, fmt.Errorf("dry-run template rendering failed for step %q: %w", step.Name, err)
+	}
+
+	logging.L().Infof("[DRY RUN] would execute step %q: %s", step.Name, rendered)
+
+	outputs := step.DryRunOutputs
+	if outputs == nil {
+		outputs = map[string]string{}
+	}
+	return &ActResult{
+		Outputs: outputs,
+	}, nil
+}
+
+// CleanupStep implements Executor for dryRunExecutor: it logs what would
+// have been cleaned up, in the same reverse order cleanup normally runs,
+// without invoking the real cleanup action.
+func (dryRunExecutor) CleanupStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	logging.L().Infof("[DRY RUN] would clean up step %q", step.Name)
+	return &ActResult{}, nil
+}
+
+// executorFor returns the Executor that RunSteps/cleanup should use for
+// execCtx: Cfg.Executor if one is configured (even when DryRun is set),
+// else the simulation backend when DryRun is configured, otherwise the
+// live executor that actually performs each step's action.
+func executorFor(execCtx *TTPExecutionContext) Executor {
+	if execCtx.Cfg.Executor != nil {
+		return execCtx.Cfg.Executor
+	}
+	if execCtx.Cfg.DryRun {
+		return dryRunExecutor{}
+	}
+	return liveExecutor{}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package blocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunExecutorExecuteStepReturnsConfiguredOutputs(t *testing.T) {
+	step := &Step{
+		Name:          "create-file",
+		Inline:        "touch /tmp/whatever",
+		DryRunOutputs: map[string]string{"path": "/tmp/whatever"},
+	}
+
+	result, err := dryRunExecutor{}.ExecuteStep(step, TTPExecutionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"path": "/tmp/whatever"}, result.Outputs)
+}
+
+func TestDryRunExecutorExecuteStepDefaultsOutputsToEmptyMap(t *testing.T) {
+	step := &Step{Name: "no-outputs", Inline: "echo hi"}
+
+	result, err := dryRunExecutor{}.ExecuteStep(step, TTPExecutionContext{})
+	require.NoError(t, err)
+	assert.NotNil(t, result.Outputs)
+	assert.Empty(t, result.Outputs)
+}
+
+func TestDryRunExecutorExecuteStepRejectsInvalidStep(t *testing.T) {
+	step := &Step{Inline: "echo hi"}
+
+	_, err := dryRunExecutor{}.ExecuteStep(step, TTPExecutionContext{})
+	assert.Error(t, err)
+}
+
+func TestDryRunExecutorCleanupStepNeverRunsCleanupInline(t *testing.T) {
+	step := &Step{Name: "cleans-up", CleanupInline: "rm -rf /this/should/never/run"}
+
+	result, err := dryRunExecutor{}.CleanupStep(step, TTPExecutionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, &ActResult{}, result)
+}
+
+func TestExecutorForSelectsBackend(t *testing.T) {
+	live := executorFor(&TTPExecutionContext{})
+	assert.IsType(t, liveExecutor{}, live)
+
+	dryRun := executorFor(&TTPExecutionContext{Cfg: TTPExecutionConfig{DryRun: true}})
+	assert.IsType(t, dryRunExecutor{}, dryRun)
+
+	custom := &fakeExecutor{}
+	configured := executorFor(&TTPExecutionContext{Cfg: TTPExecutionConfig{DryRun: true, Executor: custom}})
+	assert.Same(t, custom, configured)
+}
+
+type fakeExecutor struct{}
+
+func (f *fakeExecutor) ExecuteStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return &ActResult{}, nil
+}
+
+func (f *fakeExecutor) CleanupStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return &ActResult{}, nil
+}
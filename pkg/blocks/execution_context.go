@@ -0,0 +1,88 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"time"
+)
+
+// TTPExecutionConfig controls how a TTP run behaves: cleanup timing, and
+// the reporting/simulation options layered on top of it by later
+// features (findings output, dry-run).
+//
+// **Attributes:**
+//
+// NoCleanup: If set, cleanup is skipped entirely after the run.
+// CleanupDelaySeconds: How long to wait before running cleanup.
+// FindingsOutputPath: If set, a findings report is written here after the run.
+// FindingsFormat: The findings report format ("json" or "sarif"); defaults to "json".
+// DryRun: If set, steps are validated and rendered but never actually
+// executed; executorFor swaps in the simulation backend for this run.
+// Executor: If set, used in place of the default live/dry-run executor
+// selection, mainly so tests can substitute a fake.
+// DefaultStepTimeout: The Timeout applied to a step that doesn't set its
+// own, e.g. via the `--default-step-timeout` CLI flag. Zero means no
+// default timeout.
+// DefaultRetryPolicy: The RetryPolicy applied to a step that doesn't set
+// its own (i.e. whose RetryPolicy.MaxAttempts is zero), e.g. via the
+// `--default-retries` CLI flag.
+type TTPExecutionConfig struct {
+	NoCleanup           bool
+	CleanupDelaySeconds int
+	FindingsOutputPath  string
+	FindingsFormat      string
+	DryRun              bool
+	Executor            Executor
+	DefaultStepTimeout  time.Duration
+	DefaultRetryPolicy  RetryPolicy
+}
+
+// TTPExecutionContext carries the configuration and accumulated state
+// for a single TTP run.
+//
+// **Attributes:**
+//
+// Cfg: The configuration for this run.
+// StepResults: The results of each step executed so far.
+type TTPExecutionContext struct {
+	Cfg         TTPExecutionConfig
+	StepResults *StepResultsRecord
+	ctx         context.Context
+}
+
+// Context returns the context governing this run, defaulting to
+// context.Background() if WithContext was never called - e.g. for a
+// TTPExecutionContext built directly by callers that don't care about
+// per-step timeouts.
+func (e TTPExecutionContext) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// WithContext returns a copy of e whose Context() is ctx, used by
+// runStepWithTimeout to scope a single step's attempt to a deadline
+// without affecting the TTPExecutionContext its caller holds.
+func (e TTPExecutionContext) WithContext(ctx context.Context) TTPExecutionContext {
+	e.ctx = ctx
+	return e
+}
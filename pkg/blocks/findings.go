@@ -0,0 +1,232 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Outcome describes the result of a single probe (step) within a TTP run,
+// in the vocabulary used by SIEM/Scorecard-style reporting pipelines.
+type Outcome string
+
+// The set of outcomes a Finding can have.
+const (
+	OutcomePositive      Outcome = "Positive"
+	OutcomeNegative      Outcome = "Negative"
+	OutcomeNotApplicable Outcome = "NotApplicable"
+	OutcomeError         Outcome = "Error"
+)
+
+// RemediationEffort is a rough sizing of the work needed to act on a
+// Finding, surfaced to operators triaging a findings report.
+type RemediationEffort string
+
+// The set of efforts a Remediation can require.
+const (
+	EffortLow    RemediationEffort = "Low"
+	EffortMedium RemediationEffort = "Medium"
+	EffortHigh   RemediationEffort = "High"
+)
+
+// Remediation describes the suggested follow-up for a Finding.
+//
+// **Attributes:**
+//
+// Effort: A rough sizing of the work needed to act on the finding.
+// Text: A human-readable description of the suggested remediation.
+type Remediation struct {
+	Effort RemediationEffort `json:"effort" yaml:"effort,omitempty"`
+	Text   string            `json:"text" yaml:"text,omitempty"`
+}
+
+// Location identifies where in a TTP a Finding originated.
+//
+// **Attributes:**
+//
+// File: The path to the TTP YAML file.
+// StepIndex: The zero-based index of the step within the TTP.
+type Location struct {
+	File      string `json:"file"`
+	StepIndex int    `json:"stepIndex"`
+}
+
+// Finding is a single machine-readable result produced by a TTP step,
+// combining the step's outcome with the MITRE ATT&CK metadata of the TTP
+// it came from. A TTP run produces one Finding per step it runs; a run
+// that stops at a failing step has no Findings for the steps after it.
+//
+// **Attributes:**
+//
+// Probe: A stable ID derived from the TTP name and step name, used to
+// correlate the same logical check across runs.
+// Outcome: Whether the probe succeeded, failed, did not apply, or errored.
+// Remediation: The suggested follow-up, if any.
+// MitreAttack: The MITRE ATT&CK tactics/techniques/subtechniques this
+// finding's TTP is associated with.
+// Location: Where in the TTP this finding originated.
+// Outputs: The captured `outputs` values for the step, if any were
+// declared.
+// Attempts: How many times the step's action was attempted before this
+// finding's outcome was reached, so a report can surface flake data for
+// steps with a RetryPolicy. Always at least 1.
+type Finding struct {
+	Probe       string            `json:"probe"`
+	Outcome     Outcome           `json:"outcome"`
+	Remediation Remediation       `json:"remediation"`
+	MitreAttack MitreAttack       `json:"mitreAttack"`
+	Location    Location          `json:"location"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	Attempts    int               `json:"attempts"`
+}
+
+// probeID derives a stable, deterministic probe ID for a step from its
+// TTP and step names, so downstream tooling can diff the same probe
+// across separate runs even as unrelated steps are added or removed.
+func probeID(ttpName, stepName string) string {
+	sum := sha256.Sum256([]byte(ttpName + "/" + stepName))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// FindingsFormatter renders a set of Findings to a specific on-disk
+// artifact format.
+type FindingsFormatter interface {
+	// Format renders findings to their serialized representation.
+	Format(findings []Finding) ([]byte, error)
+}
+
+// JSONFindingsFormatter renders findings as a flat JSON array.
+type JSONFindingsFormatter struct{}
+
+// Format implements FindingsFormatter for JSONFindingsFormatter.
+func (f *JSONFindingsFormatter) Format(findings []Finding) ([]byte, error) {
+	out, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal findings to JSON: %w", err)
+	}
+	return out, nil
+}
+
+// sarifResult and sarifLog are a minimal subset of the SARIF 2.1.0
+// schema - just enough structure to carry a Finding's outcome,
+// remediation, and location so the report can be consumed by standard
+// SARIF viewers (e.g. GitHub code scanning).
+type sarifResult struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name string `json:"name"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// SARIFFindingsFormatter renders findings as a SARIF 2.1.0 log, so they
+// can be consumed by standard code-scanning tooling.
+type SARIFFindingsFormatter struct{}
+
+// Format implements FindingsFormatter for SARIFFindingsFormatter.
+func (f *SARIFFindingsFormatter) Format(findings []Finding) ([]byte, error) {
+	run := sarifRun{}
+	run.Tool.Driver.Name = "ttpforge"
+	for _, finding := range findings {
+		if finding.Outcome != OutcomeNegative && finding.Outcome != OutcomeError {
+			continue
+		}
+		result := sarifResult{
+			RuleID: finding.Probe,
+			Level:  sarifLevel(finding.Outcome),
+		}
+		result.Message.Text = finding.Remediation.Text
+		loc := sarifLocation{}
+		loc.PhysicalLocation.ArtifactLocation.URI = finding.Location.File
+		loc.PhysicalLocation.Region.StartLine = finding.Location.StepIndex + 1
+		result.Locations = []sarifLocation{loc}
+		run.Results = append(run.Results, result)
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	out, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal findings to SARIF: %w", err)
+	}
+	return out, nil
+}
+
+func sarifLevel(outcome Outcome) string {
+	if outcome == OutcomeError {
+		return "error"
+	}
+	return "warning"
+}
+
+// NewFindingsFormatter returns the FindingsFormatter for the named
+// format, as configured via TTPExecutionConfig.FindingsFormat.
+//
+// **Parameters:**
+//
+// format: The configured format name, either "json" or "sarif".
+//
+// **Returns:**
+//
+// FindingsFormatter: The formatter for the requested format.
+// error: An error if the format is not recognized.
+func NewFindingsFormatter(format string) (FindingsFormatter, error) {
+	switch format {
+	case "", "json":
+		return &JSONFindingsFormatter{}, nil
+	case "sarif":
+		return &SARIFFindingsFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized findings format %q", format)
+	}
+}
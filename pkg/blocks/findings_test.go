@@ -0,0 +1,133 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package blocks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeIDIsStableAndUnique(t *testing.T) {
+	first := probeID("my-ttp", "step-one")
+	again := probeID("my-ttp", "step-one")
+	other := probeID("my-ttp", "step-two")
+
+	assert.Equal(t, first, again, "probe ID must be deterministic for the same TTP/step names")
+	assert.NotEqual(t, first, other, "different steps must get different probe IDs")
+}
+
+func TestRunStepsRecordsPositiveFindingsOnSuccess(t *testing.T) {
+	ttp := TTP{
+		PreambleFields: PreambleFields{Name: "findings-ttp"},
+		Steps: []Step{
+			{Name: "first"},
+			{Name: "second"},
+		},
+	}
+	execCtx := &TTPExecutionContext{Cfg: TTPExecutionConfig{NoCleanup: true}}
+
+	_, _, err := ttp.RunSteps(execCtx)
+	require.NoError(t, err)
+
+	require.Len(t, ttp.findings, 2)
+	for _, finding := range ttp.findings {
+		assert.Equal(t, OutcomePositive, finding.Outcome)
+		assert.Equal(t, 1, finding.Attempts, "a step with no RetryPolicy should report exactly one attempt")
+	}
+	assert.NotEqual(t, ttp.findings[0].Probe, ttp.findings[1].Probe)
+}
+
+func TestNewFindingUsesConfiguredMetadata(t *testing.T) {
+	ttp := TTP{
+		PreambleFields: PreambleFields{
+			Name:        "findings-ttp",
+			MitreAttack: MitreAttack{Tactics: []string{"TA0001"}},
+		},
+		Path: "ttps/my-ttp.yaml",
+	}
+	step := Step{Name: "first", Remediation: Remediation{Effort: EffortHigh, Text: "fix it"}}
+
+	finding := ttp.newFinding(0, step, OutcomeNegative, map[string]string{"out": "val"}, 3)
+
+	assert.Equal(t, OutcomeNegative, finding.Outcome)
+	assert.Equal(t, step.Remediation, finding.Remediation)
+	assert.Equal(t, ttp.MitreAttack, finding.MitreAttack)
+	assert.Equal(t, Location{File: "ttps/my-ttp.yaml", StepIndex: 0}, finding.Location)
+	assert.Equal(t, map[string]string{"out": "val"}, finding.Outputs)
+	assert.Equal(t, 3, finding.Attempts)
+}
+
+func TestRunStepsRecordsAttemptCountOnFinding(t *testing.T) {
+	calls := 0
+	ttp := TTP{
+		PreambleFields: PreambleFields{Name: "flaky-findings-ttp"},
+		Steps: []Step{
+			{
+				Name: "flaky",
+				RetryPolicy: RetryPolicy{
+					MaxAttempts: 3,
+					RetryOn:     []RetryCondition{RetryOnExitCodes},
+					ExitCodes:   []int{1},
+				},
+			},
+		},
+	}
+	execCtx := &TTPExecutionContext{
+		Cfg: TTPExecutionConfig{
+			NoCleanup: true,
+			Executor: &countingExecutor{fn: func() (*ActResult, error) {
+				calls++
+				if calls < 3 {
+					return &ActResult{ExitCode: 1}, assert.AnError
+				}
+				return &ActResult{ExitCode: 0}, nil
+			}},
+		},
+	}
+
+	_, _, err := ttp.RunSteps(execCtx)
+	require.NoError(t, err)
+
+	require.Len(t, ttp.findings, 1)
+	assert.Equal(t, 3, ttp.findings[0].Attempts)
+}
+
+func TestNewFindingsFormatter(t *testing.T) {
+	findings := []Finding{{Probe: "abc123", Outcome: OutcomeNegative, Remediation: Remediation{Effort: EffortLow, Text: "do the thing"}}}
+
+	jsonFormatter, err := NewFindingsFormatter("json")
+	require.NoError(t, err)
+	rendered, err := jsonFormatter.Format(findings)
+	require.NoError(t, err)
+	var decoded []Finding
+	require.NoError(t, json.Unmarshal(rendered, &decoded))
+	assert.Equal(t, findings, decoded)
+
+	sarifFormatter, err := NewFindingsFormatter("sarif")
+	require.NoError(t, err)
+	rendered, err = sarifFormatter.Format(findings)
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), "abc123")
+
+	_, err = NewFindingsFormatter("xml")
+	require.Error(t, err)
+}
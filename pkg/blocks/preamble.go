@@ -0,0 +1,40 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import "fmt"
+
+// PreambleFields holds the metadata common to every TTP: its identity,
+// documentation, MITRE ATT&CK mapping, and the requirements that must be
+// met before it can run.
+type PreambleFields struct {
+	Name         string       `yaml:"name"`
+	Description  string       `yaml:"description,omitempty"`
+	MitreAttack  MitreAttack  `yaml:"mitre,omitempty"`
+	Requirements Requirements `yaml:"requirements,omitempty"`
+}
+
+// Validate checks that the preamble's required fields are populated.
+func (p PreambleFields) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("TTP is missing a name")
+	}
+	return nil
+}
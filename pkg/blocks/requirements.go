@@ -0,0 +1,39 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import "github.com/facebookincubator/ttpforge/pkg/checks"
+
+// Requirements describes the preconditions - platform, installed
+// tooling, and so on - that must be met before a TTP is allowed to run.
+type Requirements struct {
+	Checks []checks.Check `yaml:"checks,omitempty"`
+}
+
+// Verify checks that every requirement is met in verificationCtx,
+// returning the first failure encountered.
+func (r Requirements) Verify(verificationCtx checks.VerificationContext) error {
+	for _, check := range r.Checks {
+		if err := check.Verify(verificationCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+// BackoffKind selects how RetryPolicy spaces out retry attempts.
+type BackoffKind string
+
+// The supported backoff kinds for a RetryPolicy.
+const (
+	BackoffFixed       BackoffKind = "fixed"
+	BackoffExponential BackoffKind = "exponential"
+)
+
+// RetryCondition names a reason a failed attempt should be retried.
+type RetryCondition string
+
+// The supported retry conditions for a RetryPolicy.
+const (
+	RetryOnExitCodes RetryCondition = "exit_codes"
+	RetryOnRegex     RetryCondition = "regex"
+	RetryOnTimeout   RetryCondition = "timeout"
+)
+
+// RetryPolicy configures how many times, and how, a step should be
+// retried after a failed attempt. On its own the zero value means "no
+// retries"; RunSteps, however, replaces a step's policy whose MaxAttempts
+// is zero with TTPExecutionConfig.DefaultRetryPolicy.
+//
+// **Attributes:**
+//
+// MaxAttempts: The maximum number of times to attempt the step, including
+// the first attempt. One (or less) means "no retries"; zero additionally
+// makes RunSteps use the run's DefaultRetryPolicy instead.
+// Backoff: Whether the delay between attempts is fixed or exponential.
+// InitialDelay: The delay before the first retry.
+// MaxDelay: The largest delay allowed between retries, capping exponential growth.
+// RetryOn: Which failure conditions should trigger a retry. Defaults to
+// retrying on any error.
+// ExitCodes: The process exit codes that satisfy the `exit_codes` RetryOn
+// condition. Ignored unless RetryOn includes RetryOnExitCodes.
+// Regex: A pattern matched against the attempt's captured stdout/stderr
+// to satisfy the `regex` RetryOn condition. Ignored unless RetryOn
+// includes RetryOnRegex.
+type RetryPolicy struct {
+	MaxAttempts  int              `yaml:"max_attempts,omitempty"`
+	Backoff      BackoffKind      `yaml:"backoff,omitempty"`
+	InitialDelay time.Duration    `yaml:"initial_delay,omitempty"`
+	MaxDelay     time.Duration    `yaml:"max_delay,omitempty"`
+	RetryOn      []RetryCondition `yaml:"retry_on,omitempty"`
+	ExitCodes    []int            `yaml:"exit_codes,omitempty"`
+	Regex        string           `yaml:"regex,omitempty"`
+}
+
+// attempts returns the number of attempts this policy allows, treating
+// an unset or invalid MaxAttempts as "no retries".
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// delayFor returns how long RunSteps should sleep before the given retry
+// attempt (attemptIdx is zero-based: the delay before the *second*
+// attempt is delayFor(1)).
+func (p RetryPolicy) delayFor(attemptIdx int) time.Duration {
+	delay := p.InitialDelay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attemptIdx; i++ {
+			delay *= 2
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// shouldRetry reports whether a failed attempt - having produced result,
+// err and timedOut - should be retried under this policy. An empty
+// RetryOn list retries on any failure: once a policy with attempts > 1
+// is configured, any error is eligible for retry unless RetryOn narrows
+// it down. result may be nil, e.g. when the step timed out before
+// producing one.
+func (p RetryPolicy) shouldRetry(result *ActResult, err error, timedOut bool) bool {
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	for _, cond := range p.RetryOn {
+		switch cond {
+		case RetryOnTimeout:
+			if timedOut {
+				return true
+			}
+		case RetryOnExitCodes:
+			if result != nil && p.matchesExitCode(result.ExitCode) {
+				return true
+			}
+		case RetryOnRegex:
+			if result != nil && p.matchesRegex(result.Stdout) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesExitCode reports whether exitCode is one of p.ExitCodes.
+func (p RetryPolicy) matchesExitCode(exitCode int) bool {
+	for _, code := range p.ExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRegex reports whether p.Regex matches output. An empty or
+// invalid pattern never matches, rather than retrying (or failing the
+// run) on a configuration mistake.
+func (p RetryPolicy) matchesRegex(output string) bool {
+	if p.Regex == "" {
+		return false
+	}
+	matched, err := regexp.MatchString(p.Regex, output)
+	if err != nil {
+		logging.L().Errorf("invalid retry_on regex %q: %v", p.Regex, err)
+		return false
+	}
+	return matched
+}
+
+// runStepWithTimeout runs a single attempt of step, bounding it by
+// step.Timeout (if set) via a context threaded through execCtx. It
+// reports whether the attempt failed specifically because it timed out,
+// so RetryPolicy.RetryOn's `timeout` condition can be honored.
+func runStepWithTimeout(executor Executor, step *Step, execCtx TTPExecutionContext) (*ActResult, error, bool) {
+	if step.Timeout <= 0 {
+		result, err := executor.ExecuteStep(step, execCtx)
+		return result, err, false
+	}
+
+	ctx, cancel := context.WithTimeout(execCtx.Context(), step.Timeout)
+	defer cancel()
+	execCtx = execCtx.WithContext(ctx)
+
+	type stepOutcome struct {
+		result *ActResult
+		err    error
+	}
+	done := make(chan stepOutcome, 1)
+	go func() {
+		result, err := executor.ExecuteStep(step, execCtx)
+		done <- stepOutcome{result, err}
+	}()
+
+	select {
+	case outcome := <-done:
+		return outcome.result, outcome.err, false
+	case <-ctx.Done():
+		return nil, fmt.Errorf("step %q timed out after %v", step.Name, step.Timeout), true
+	}
+}
+
+// runAttemptsWithPolicy runs attempt up to policy's MaxAttempts times,
+// sleeping per policy's backoff between tries, and records every attempt
+// (successful or not) into execResult.Attempts so findings/reporting can
+// show flake data. attempt should perform one full try of a step -
+// executing it and, if the caller wants retries to cover them,
+// re-running its success checks - and report whether that try timed out.
+// runAttemptsWithPolicy returns the last attempt's result and error.
+func runAttemptsWithPolicy(stepName string, policy RetryPolicy, execResult *ExecutionResult, attempt func() (*ActResult, error, bool)) (*ActResult, error) {
+	attempts := policy.attempts()
+
+	var lastResult *ActResult
+	var lastErr error
+	for attemptIdx := 0; attemptIdx < attempts; attemptIdx++ {
+		if attemptIdx > 0 {
+			delay := policy.delayFor(attemptIdx)
+			if delay > 0 {
+				logging.L().Infof("[+] Retrying step %q (attempt %d/%d) after %v", stepName, attemptIdx+1, attempts, delay)
+				time.Sleep(delay)
+			}
+		}
+
+		result, err, timedOut := attempt()
+		lastResult, lastErr = result, err
+		execResult.Attempts = append(execResult.Attempts, *safeActResult(result))
+
+		if err == nil {
+			return result, nil
+		}
+		if attemptIdx == attempts-1 || !policy.shouldRetry(result, err, timedOut) {
+			return result, err
+		}
+		logging.L().Errorf("step %q attempt %d/%d failed: %v", stepName, attemptIdx+1, attempts, err)
+	}
+	return lastResult, lastErr
+}
+
+// safeActResult returns result, or an empty ActResult if result is nil,
+// so a failed attempt with no result still has a well-formed entry in
+// ExecutionResult.Attempts.
+func safeActResult(result *ActResult) *ActResult {
+	if result == nil {
+		return &ActResult{}
+	}
+	return result
+}
@@ -0,0 +1,286 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package blocks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy RetryPolicy
+		want   int
+	}{
+		{name: "zero value means no retries", policy: RetryPolicy{}, want: 1},
+		{name: "MaxAttempts of 1 means no retries", policy: RetryPolicy{MaxAttempts: 1}, want: 1},
+		{name: "negative MaxAttempts means no retries", policy: RetryPolicy{MaxAttempts: -1}, want: 1},
+		{name: "MaxAttempts is honored", policy: RetryPolicy{MaxAttempts: 4}, want: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.attempts())
+		})
+	}
+}
+
+func TestRetryPolicyDelayFor(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy RetryPolicy
+		idx    int
+		want   time.Duration
+	}{
+		{
+			name:   "fixed backoff is constant",
+			policy: RetryPolicy{Backoff: BackoffFixed, InitialDelay: time.Second},
+			idx:    3,
+			want:   time.Second,
+		},
+		{
+			name:   "exponential backoff doubles per attempt",
+			policy: RetryPolicy{Backoff: BackoffExponential, InitialDelay: time.Second},
+			idx:    3,
+			want:   4 * time.Second,
+		},
+		{
+			name:   "exponential backoff is capped at MaxDelay",
+			policy: RetryPolicy{Backoff: BackoffExponential, InitialDelay: time.Second, MaxDelay: 3 * time.Second},
+			idx:    3,
+			want:   3 * time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.delayFor(tc.idx))
+		})
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	genericErr := errors.New("boom")
+
+	testCases := []struct {
+		name     string
+		policy   RetryPolicy
+		result   *ActResult
+		err      error
+		timedOut bool
+		want     bool
+	}{
+		{
+			name:   "empty RetryOn retries on any error",
+			policy: RetryPolicy{},
+			err:    genericErr,
+			want:   true,
+		},
+		{
+			name:     "timeout condition matches a timed-out attempt",
+			policy:   RetryPolicy{RetryOn: []RetryCondition{RetryOnTimeout}},
+			timedOut: true,
+			want:     true,
+		},
+		{
+			name:   "timeout condition does not match a non-timeout error",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnTimeout}},
+			err:    genericErr,
+			want:   false,
+		},
+		{
+			name:   "exit_codes condition matches a configured exit code",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnExitCodes}, ExitCodes: []int{1, 2}},
+			result: &ActResult{ExitCode: 2},
+			err:    genericErr,
+			want:   true,
+		},
+		{
+			name:   "exit_codes condition does not match an unlisted exit code",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnExitCodes}, ExitCodes: []int{1, 2}},
+			result: &ActResult{ExitCode: 9},
+			err:    genericErr,
+			want:   false,
+		},
+		{
+			name:   "regex condition matches attempt stdout",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnRegex}, Regex: "connection refused"},
+			result: &ActResult{Stdout: "error: connection refused by host"},
+			err:    genericErr,
+			want:   true,
+		},
+		{
+			name:   "regex condition does not match unrelated stdout",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnRegex}, Regex: "connection refused"},
+			result: &ActResult{Stdout: "error: disk full"},
+			err:    genericErr,
+			want:   false,
+		},
+		{
+			name:   "invalid regex never matches",
+			policy: RetryPolicy{RetryOn: []RetryCondition{RetryOnRegex}, Regex: "("},
+			result: &ActResult{Stdout: "anything"},
+			err:    genericErr,
+			want:   false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.shouldRetry(tc.result, tc.err, tc.timedOut))
+		})
+	}
+}
+
+func TestRunAttemptsWithPolicyRecordsEveryAttempt(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 3, RetryOn: []RetryCondition{RetryOnExitCodes}, ExitCodes: []int{1}}
+	execResult := &ExecutionResult{}
+
+	result, err := runAttemptsWithPolicy("flaky", policy, execResult, func() (*ActResult, error, bool) {
+		calls++
+		if calls < 3 {
+			return &ActResult{ExitCode: 1}, errors.New("not yet"), false
+		}
+		return &ActResult{ExitCode: 0}, nil, false
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, execResult.Attempts, 3)
+}
+
+func TestRunStepWithTimeoutKillsHangingProcess(t *testing.T) {
+	step := &Step{Name: "hangs", Inline: "sleep 5", Timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	result, err, timedOut := runStepWithTimeout(liveExecutor{}, step, TTPExecutionContext{})
+	elapsed := time.Since(start)
+
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	assert.True(t, timedOut)
+	assert.Less(t, elapsed, 2*time.Second, "runStepWithTimeout must not wait for the full sleep 5 to complete")
+}
+
+func TestStepExecuteKillsProcessWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	execCtx := TTPExecutionContext{}.WithContext(ctx)
+	step := &Step{Name: "hangs", Inline: "sleep 5"}
+
+	start := time.Now()
+	_, err := step.Execute(execCtx)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Less(t, elapsed, 2*time.Second, "the child process should be killed by the canceled context rather than left to run to completion")
+}
+
+func TestRunStepsAppliesDefaultRetryPolicyWhenStepHasNone(t *testing.T) {
+	calls := 0
+	ttp := TTP{
+		PreambleFields: PreambleFields{Name: "default-retry-ttp"},
+		Steps: []Step{
+			{Name: "flaky", Inline: "unused"},
+		},
+	}
+	execCtx := &TTPExecutionContext{
+		Cfg: TTPExecutionConfig{
+			NoCleanup: true,
+			DefaultRetryPolicy: RetryPolicy{
+				MaxAttempts: 3,
+				RetryOn:     []RetryCondition{RetryOnExitCodes},
+				ExitCodes:   []int{1},
+			},
+			Executor: &countingExecutor{fn: func() (*ActResult, error) {
+				calls++
+				if calls < 3 {
+					return &ActResult{ExitCode: 1}, errors.New("not yet")
+				}
+				return &ActResult{ExitCode: 0}, nil
+			}},
+		},
+	}
+
+	_, _, err := ttp.RunSteps(execCtx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "the step's own zero-value RetryPolicy must fall back to Cfg.DefaultRetryPolicy")
+}
+
+func TestRunStepsLeavesExplicitStepRetryPolicyUntouched(t *testing.T) {
+	calls := 0
+	ttp := TTP{
+		PreambleFields: PreambleFields{Name: "explicit-retry-ttp"},
+		Steps: []Step{
+			{Name: "no-retry", Inline: "unused", RetryPolicy: RetryPolicy{MaxAttempts: 1}},
+		},
+	}
+	execCtx := &TTPExecutionContext{
+		Cfg: TTPExecutionConfig{
+			NoCleanup: true,
+			DefaultRetryPolicy: RetryPolicy{
+				MaxAttempts: 5,
+				RetryOn:     []RetryCondition{RetryOnExitCodes},
+				ExitCodes:   []int{1},
+			},
+			Executor: &countingExecutor{fn: func() (*ActResult, error) {
+				calls++
+				return &ActResult{ExitCode: 1}, errors.New("always fails")
+			}},
+		},
+	}
+
+	_, _, err := ttp.RunSteps(execCtx)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "a step with its own explicit RetryPolicy must not pick up Cfg.DefaultRetryPolicy")
+}
+
+type countingExecutor struct {
+	fn func() (*ActResult, error)
+}
+
+func (c *countingExecutor) ExecuteStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return c.fn()
+}
+
+func (c *countingExecutor) CleanupStep(step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	return &ActResult{}, nil
+}
+
+func TestRunAttemptsWithPolicyStopsRetryingWhenConditionDoesNotMatch(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 3, RetryOn: []RetryCondition{RetryOnExitCodes}, ExitCodes: []int{1}}
+	execResult := &ExecutionResult{}
+
+	_, err := runAttemptsWithPolicy("not-flaky", policy, execResult, func() (*ActResult, error, bool) {
+		calls++
+		return &ActResult{ExitCode: 9}, errors.New("persistent failure"), false
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "exit code 9 doesn't match the configured retry-on exit codes, so it must not retry")
+	assert.Len(t, execResult.Attempts, 1)
+}
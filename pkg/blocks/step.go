@@ -0,0 +1,125 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/checks"
+)
+
+// Step is a single action a TTP performs, plus the success checks,
+// cleanup, and remediation guidance that accompany it.
+//
+// **Attributes:**
+//
+// Name: The step's identifier, unique within its TTP.
+// Inline: A shell command to run for this step.
+// CleanupInline: A shell command to run when cleaning this step up.
+// Checks: Success checks run after the step's action completes.
+// ContinueOnCheckFailure: If set, a failed success check produces a
+// Negative finding instead of aborting the TTP run.
+// Remediation: The suggested follow-up if this step's check fails.
+// DryRunOutputs: The outputs a dry run should pretend this step produced,
+// since dryRunExecutor never actually runs Inline and so has nothing real
+// to extract `outputs:` from.
+// Timeout: The maximum duration allowed for a single attempt at this
+// step's action. Zero means RunSteps uses the run's DefaultStepTimeout,
+// which is itself zero (no timeout) unless configured.
+// RetryPolicy: How to retry this step's action if it fails. A zero
+// MaxAttempts means RunSteps uses the run's DefaultRetryPolicy, which is
+// itself "no retries" unless configured.
+type Step struct {
+	Name                   string            `yaml:"name"`
+	Inline                 string            `yaml:"inline,omitempty"`
+	CleanupInline          string            `yaml:"cleanup_inline,omitempty"`
+	Checks                 []checks.Check    `yaml:"checks,omitempty"`
+	ContinueOnCheckFailure bool              `yaml:"continue_on_check_failure,omitempty"`
+	Remediation            Remediation       `yaml:"remediation,omitempty"`
+	DryRunOutputs          map[string]string `yaml:"dry_run_outputs,omitempty"`
+	Timeout                time.Duration     `yaml:"timeout,omitempty"`
+	RetryPolicy            RetryPolicy       `yaml:"retry,omitempty"`
+}
+
+// Validate checks that the step is well-formed.
+func (s *Step) Validate(execCtx TTPExecutionContext) error {
+	if s.Name == "" {
+		return fmt.Errorf("step is missing a name")
+	}
+	return nil
+}
+
+// Execute runs this step's inline command, if any, and returns its
+// result. The command is bound to execCtx.Context(), so a timeout set
+// via runStepWithTimeout actually kills the process rather than merely
+// abandoning it.
+func (s *Step) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+	return runInline(execCtx.Context(), s.Name, s.Inline)
+}
+
+// Cleanup runs this step's configured cleanup command, if any.
+func (s *Step) Cleanup(execCtx TTPExecutionContext) (*ActResult, error) {
+	return runInline(execCtx.Context(), s.Name, s.CleanupInline)
+}
+
+// RenderTemplated returns this step's inline command as it would be
+// executed, for display in dry-run logging. Plain inline steps have no
+// templated args/env of their own to expand; it exists so dryRunExecutor
+// has something to render uniformly across step kinds, some of which
+// (e.g. file/subttp steps) do have templated fields.
+func (s *Step) RenderTemplated(execCtx TTPExecutionContext) (string, error) {
+	return s.Inline, nil
+}
+
+// ShouldCleanupOnFailure reports whether this step's cleanup must run
+// even when its action failed. Plain inline steps have nothing that
+// could have partially succeeded, so they don't need this; it exists for
+// parity with step kinds (e.g. SubTTP) whose substeps can.
+func (s *Step) ShouldCleanupOnFailure() bool {
+	return false
+}
+
+// runInline runs command as a shell command bound to ctx, capturing its
+// combined output and exit code into an ActResult. An empty command is a
+// no-op that succeeds trivially. Using exec.CommandContext means a
+// canceled/expired ctx (e.g. from a step's Timeout) kills the child
+// process instead of merely abandoning it to run to completion in the
+// background.
+func runInline(ctx context.Context, stepName, command string) (*ActResult, error) {
+	if command == "" {
+		return &ActResult{}, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	runErr := cmd.Run()
+	result := &ActResult{ExitCode: cmd.ProcessState.ExitCode(), Stdout: output.String()}
+	if runErr != nil {
+		return result, fmt.Errorf("step %q failed: %w: %s", stepName, runErr, output.String())
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+// ExecutionResult records what happened when a single step ran: its
+// action's result, and - once cleanup for it has run - its cleanup's
+// result too.
+type ExecutionResult struct {
+	ActResult
+	Cleanup *ActResult
+	// Attempts records every attempt runAttemptsWithPolicy made at this
+	// step's action, successful or not, so findings/reporting can show
+	// flake data even though only the last attempt's result is promoted
+	// to ActResult.
+	Attempts []ActResult
+}
+
+// StepResultsRecord indexes a TTP run's ExecutionResults both by step
+// name and by step order, so later steps (and reporting code) can look a
+// prior step's result up either way.
+type StepResultsRecord struct {
+	ByName  map[string]*ExecutionResult
+	ByIndex []*ExecutionResult
+}
+
+// NewStepResultsRecord returns an empty StepResultsRecord ready to be
+// populated as a TTP's steps run.
+func NewStepResultsRecord() *StepResultsRecord {
+	return &StepResultsRecord{
+		ByName: make(map[string]*ExecutionResult),
+	}
+}
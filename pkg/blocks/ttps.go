@@ -33,6 +33,29 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// writeFindingsReport renders the findings gathered during a TTP run
+// with the formatter configured for execCtx and writes them to
+// execCtx.Cfg.FindingsOutputPath. It is a no-op if no output path was
+// configured.
+func (t *TTP) writeFindingsReport(findings []Finding, execCtx *TTPExecutionContext) error {
+	if execCtx.Cfg.FindingsOutputPath == "" {
+		return nil
+	}
+	formatter, err := NewFindingsFormatter(execCtx.Cfg.FindingsFormat)
+	if err != nil {
+		return fmt.Errorf("failed to build findings formatter: %w", err)
+	}
+	rendered, err := formatter.Format(findings)
+	if err != nil {
+		return fmt.Errorf("failed to render findings report: %w", err)
+	}
+	if err := afero.WriteFile(afero.NewOsFs(), execCtx.Cfg.FindingsOutputPath, rendered, 0o644); err != nil {
+		return fmt.Errorf("failed to write findings report to %q: %w", execCtx.Cfg.FindingsOutputPath, err)
+	}
+	logging.L().Infof("[+] Wrote findings report to %v", execCtx.Cfg.FindingsOutputPath)
+	return nil
+}
+
 // TTP represents the top-level structure for a TTP
 // (Tactics, Techniques, and Procedures) object.
 //
@@ -47,6 +70,13 @@ type TTP struct {
 	Steps          []Step            `yaml:"steps,omitempty,flow"`
 	// Omit WorkDir, but expose for testing.
 	WorkDir string `yaml:"-"`
+	// Path is the filesystem path this TTP was loaded from (set by
+	// LoadTTP), used for reporting. Empty for TTPs built in-memory (e.g.
+	// in tests).
+	Path string `yaml:"-"`
+	// findings accumulates the Finding produced by the most recent
+	// RunSteps call, for use by Execute's findings report.
+	findings []Finding `yaml:"-"`
 }
 
 // MitreAttack represents mappings to the MITRE ATT&CK framework.
@@ -198,6 +228,9 @@ func (t *TTP) Execute(execCtx *TTPExecutionContext) (*StepResultsRecord, error)
 	} else {
 		logging.L().Info("TTP Completed Successfully! ✅")
 	}
+	if err := t.writeFindingsReport(t.findings, execCtx); err != nil {
+		logging.L().Errorf("[*] Error writing findings report: %v", err)
+	}
 	if !execCtx.Cfg.NoCleanup {
 		if execCtx.Cfg.CleanupDelaySeconds > 0 {
 			logging.L().Infof("[*] Sleeping for Requested Cleanup Delay of %v Seconds", execCtx.Cfg.CleanupDelaySeconds)
@@ -240,13 +273,61 @@ func (t *TTP) RunSteps(execCtx *TTPExecutionContext) (*StepResultsRecord, int, e
 	stepResults := NewStepResultsRecord()
 	execCtx.StepResults = stepResults
 	firstStepToCleanupIdx := -1
+	t.findings = nil
+	executor := executorFor(execCtx)
 	for stepIdx, step := range t.Steps {
 		stepCopy := step
+		// a step that doesn't set its own Timeout/RetryPolicy falls back
+		// to the run's configured defaults, so an operator can apply a
+		// policy across an entire TTP library without editing every TTP
+		if stepCopy.Timeout <= 0 {
+			stepCopy.Timeout = execCtx.Cfg.DefaultStepTimeout
+		}
+		if stepCopy.RetryPolicy.MaxAttempts == 0 {
+			stepCopy.RetryPolicy = execCtx.Cfg.DefaultRetryPolicy
+		}
 		logging.DividerThin()
 		logging.L().Infof("Executing Step #%d: %q", stepIdx+1, step.Name)
 
-		// core execution - run the step action
-		stepResult, err := stepCopy.Execute(*execCtx)
+		// core execution - run the step action (or simulate it, in dry-run
+		// mode), retrying per the step's RetryPolicy and bounding each
+		// attempt by its Timeout; every attempt is recorded on execResult
+		// so findings/reporting can show flake data
+		execResult := &ExecutionResult{}
+		checksFailed := false
+		stepResult, err := runAttemptsWithPolicy(stepCopy.Name, stepCopy.RetryPolicy, execResult, func() (*ActResult, error, bool) {
+			result, execErr, timedOut := runStepWithTimeout(executor, &stepCopy, *execCtx)
+			if execErr != nil {
+				return result, execErr, timedOut
+			}
+
+			// if the user specified custom success checks, run them now -
+			// re-run on every attempt so a flaky check gets the same
+			// retry treatment as a flaky action. Dry runs never perform
+			// the step's actual action, so checks would just be verifying
+			// unrelated, unchanged system state - skip them entirely,
+			// the same way ExecuteStep is swapped out for the simulation
+			// backend.
+			checksFailed = false
+			if execCtx.Cfg.DryRun {
+				return result, nil, false
+			}
+			verificationCtx := checks.VerificationContext{
+				FileSystem: afero.NewOsFs(),
+			}
+			for checkIdx, check := range step.Checks {
+				if checkErr := check.Verify(verificationCtx); checkErr != nil {
+					if step.ContinueOnCheckFailure {
+						logging.L().Errorf("success check %d of step %q failed (continuing): %v", checkIdx+1, step.Name, checkErr)
+						checksFailed = true
+						continue
+					}
+					return result, fmt.Errorf("success check %d of step %q failed: %w", checkIdx+1, step.Name, checkErr), false
+				}
+				logging.L().Debugf("Success check %d (%q) of step %q PASSED", checkIdx+1, check.Msg, step.Name)
+			}
+			return result, nil, false
+		})
 
 		// this part is tricky - SubTTP steps
 		// must be cleaned up even on failure
@@ -257,36 +338,48 @@ func (t *TTP) RunSteps(execCtx *TTPExecutionContext) (*StepResultsRecord, int, e
 			if step.ShouldCleanupOnFailure() {
 				logging.L().Infof("[+] Cleaning up failed step %s", step.Name)
 				logging.L().Infof("[+] Full Cleanup will Run Afterward")
-				_, cleanupErr := step.Cleanup(*execCtx)
+				_, cleanupErr := executor.CleanupStep(&step, *execCtx)
 				if cleanupErr != nil {
 					logging.L().Errorf("error cleaning up failed step %v: %v", step.Name, err)
 				}
 			}
+			t.findings = append(t.findings, t.newFinding(stepIdx, step, OutcomeError, nil, len(execResult.Attempts)))
 			return nil, firstStepToCleanupIdx, err
 		}
 
-		// if the user specified custom success checks, run them now
-		verificationCtx := checks.VerificationContext{
-			FileSystem: afero.NewOsFs(),
-		}
-		for checkIdx, check := range step.Checks {
-			if err := check.Verify(verificationCtx); err != nil {
-				return nil, firstStepToCleanupIdx, fmt.Errorf("success check %d of step %q failed: %w", checkIdx+1, step.Name, err)
-			}
-			logging.L().Debugf("Success check %d (%q) of step %q PASSED", checkIdx+1, check.Msg, step.Name)
+		outcome := OutcomePositive
+		if checksFailed {
+			outcome = OutcomeNegative
 		}
+		t.findings = append(t.findings, t.newFinding(stepIdx, step, outcome, stepResult.Outputs, len(execResult.Attempts)))
 
 		// step execution successful - record results
 		firstStepToCleanupIdx++
-		execResult := &ExecutionResult{
-			ActResult: *stepResult,
-		}
+		execResult.ActResult = *stepResult
 		stepResults.ByName[step.Name] = execResult
 		stepResults.ByIndex = append(stepResults.ByIndex, execResult)
 	}
 	return stepResults, firstStepToCleanupIdx, nil
 }
 
+// newFinding builds the Finding for a single executed step, propagating
+// the TTP's MITRE ATT&CK metadata, the step's configured remediation, and
+// how many attempts runAttemptsWithPolicy made at it.
+func (t *TTP) newFinding(stepIdx int, step Step, outcome Outcome, outputs map[string]string, attempts int) Finding {
+	return Finding{
+		Probe:       probeID(t.Name, step.Name),
+		Outcome:     outcome,
+		Remediation: step.Remediation,
+		MitreAttack: t.MitreAttack,
+		Location: Location{
+			File:      t.Path,
+			StepIndex: stepIdx,
+		},
+		Outputs:  outputs,
+		Attempts: attempts,
+	}
+}
+
 func (t *TTP) startCleanupAtStepIdx(firstStepToCleanupIdx int, execCtx *TTPExecutionContext) ([]*ActResult, error) {
 	// go to the configuration directory for this TTP
 	changeBack, err := t.chdir()
@@ -297,12 +390,13 @@ func (t *TTP) startCleanupAtStepIdx(firstStepToCleanupIdx int, execCtx *TTPExecu
 
 	logging.DividerThick()
 	logging.L().Infof("CLEANING UP TTP: %q", t.Name)
+	executor := executorFor(execCtx)
 	var cleanupResults []*ActResult
 	for cleanupIdx := firstStepToCleanupIdx; cleanupIdx >= 0; cleanupIdx-- {
 		stepToCleanup := t.Steps[cleanupIdx]
 		logging.DividerThin()
 		logging.L().Infof("Cleaning Up Step #%d: %q", cleanupIdx+1, stepToCleanup.Name)
-		cleanupResult, err := stepToCleanup.Cleanup(*execCtx)
+		cleanupResult, err := executor.CleanupStep(&stepToCleanup, *execCtx)
 		// must be careful to put these in step order, not in execution (reverse) order
 		cleanupResults = append([]*ActResult{cleanupResult}, cleanupResults...)
 		if err != nil {
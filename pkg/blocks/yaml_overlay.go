@@ -0,0 +1,391 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadTTP reads the TTP at ttpPath, deep-merges any `.local` (or
+// per-environment) overlay on top of it via LoadTTPOverlays, and
+// unmarshals the result into a TTP ready for Validate/Execute.
+//
+// **Parameters:**
+//
+// fsys: The filesystem to read the TTP and its overlays from.
+// ttpPath: The path to the base TTP YAML file.
+//
+// **Returns:**
+//
+// *TTP: The loaded TTP, with overlays applied.
+// error: An error if the file can't be read, merged, or parsed.
+func LoadTTP(fsys afero.Fs, ttpPath string) (*TTP, error) {
+	merged, err := LoadTTPOverlays(fsys, ttpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var ttp TTP
+	if err := yaml.Unmarshal(merged, &ttp); err != nil {
+		return nil, fmt.Errorf("failed to parse TTP %q: %w", ttpPath, err)
+	}
+	ttp.Path = filepath.Clean(ttpPath)
+	ttp.WorkDir = filepath.Dir(ttp.Path)
+	return &ttp, nil
+}
+
+// localOverlaySuffix is appended to a TTP's filename to find its
+// operator-local overlay, e.g. `foo.yaml` -> `foo.yaml.local`.
+const localOverlaySuffix = ".local"
+
+// removeTag is a YAML tag that, when attached to a node in an overlay,
+// removes the corresponding node from the base document instead of
+// merging it.
+const removeTag = "!remove"
+
+// LoadTTPOverlays locates and deep-merges any overlays available for the
+// TTP at ttpPath (`<ttpPath>.local`, then the per-environment overlay
+// named by overlayCandidates, if any) and returns the resulting YAML
+// document. If no overlay exists, the base document is
+// returned unmodified.
+//
+// **Parameters:**
+//
+// fsys: The filesystem to read the base TTP and its overlays from.
+// ttpPath: The path to the base TTP YAML file.
+//
+// **Returns:**
+//
+// []byte: The merged YAML document ready for unmarshalling into a TTP.
+// error: An error if the base file, an overlay, or the merge itself fails.
+func LoadTTPOverlays(fsys afero.Fs, ttpPath string) ([]byte, error) {
+	base, err := afero.ReadFile(fsys, ttpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TTP %q: %w", ttpPath, err)
+	}
+
+	for _, overlayPath := range overlayCandidates(ttpPath) {
+		exists, err := afero.Exists(fsys, overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat overlay %q: %w", overlayPath, err)
+		}
+		if !exists {
+			continue
+		}
+		overlay, err := afero.ReadFile(fsys, overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read overlay %q: %w", overlayPath, err)
+		}
+		base, err = MergeTTPYAML(base, overlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply overlay %q: %w", overlayPath, err)
+		}
+	}
+
+	return base, nil
+}
+
+// overlayCandidates returns the overlay paths considered for ttpPath, in
+// the order they should be applied. The `.local` overlay is always
+// consulted; a `TTPFORGE_ENV` per-environment overlay (e.g.
+// `foo.yaml.staging`) is applied on top of it when the environment
+// variable is set, so where both overlays set the same key the
+// per-environment value wins.
+func overlayCandidates(ttpPath string) []string {
+	candidates := []string{ttpPath + localOverlaySuffix}
+	if env := os.Getenv("TTPFORGE_ENV"); env != "" {
+		candidates = append(candidates, fmt.Sprintf("%s.%s", ttpPath, env))
+	}
+	return candidates
+}
+
+// MergeTTPYAML deep-merges the overlay YAML document on top of the base
+// YAML document and returns the resulting document. Maps are merged
+// key-by-key recursively; scalars and sequences in the overlay replace
+// the value at the same path in the base; and a node tagged `!remove` (or
+// a null value nested under a `delete:` key) removes the corresponding
+// key from the base instead of merging it.
+//
+// Steps are a special case: because `steps` is a sequence, overlay
+// entries are matched against base entries by their `name:` field rather
+// than by index, so an overlay can patch `steps[i]` without needing to
+// know (or preserve) its position in the base file.
+//
+// **Parameters:**
+//
+// base: The base TTP YAML document.
+// overlay: The overlay YAML document to merge on top of base.
+//
+// **Returns:**
+//
+// []byte: The merged YAML document.
+// error: An error if either document fails to parse or the merge fails.
+func MergeTTPYAML(base, overlay []byte) ([]byte, error) {
+	var baseNode, overlayNode yaml.Node
+	if err := yaml.Unmarshal(base, &baseNode); err != nil {
+		return nil, fmt.Errorf("failed to parse base TTP YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(overlay, &overlayNode); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay YAML: %w", err)
+	}
+
+	// an empty overlay document has no content node - nothing to merge
+	if len(overlayNode.Content) == 0 {
+		return base, nil
+	}
+	if len(baseNode.Content) == 0 {
+		return overlay, nil
+	}
+
+	merged, err := mergeNodes(baseNode.Content[0], overlayNode.Content[0])
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged TTP YAML: %w", err)
+	}
+	return out, nil
+}
+
+// mergeNodes recursively merges overlay on top of base and returns the
+// resulting node. base and overlay are never mutated.
+func mergeNodes(base, overlay *yaml.Node) (*yaml.Node, error) {
+	if overlay.Tag == removeTag {
+		return nil, nil
+	}
+
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		// scalars and sequences in the overlay replace the base value outright
+		return overlay, nil
+	}
+
+	result := &yaml.Node{
+		Kind:  yaml.MappingNode,
+		Tag:   base.Tag,
+		Style: base.Style,
+	}
+
+	baseKeys := mappingKeyIndex(base)
+	overlayKeys := mappingKeyIndex(overlay)
+
+	// walk the base in order, merging in any overlay value present for that key
+	for _, key := range mappingKeyOrder(base) {
+		baseValue := base.Content[baseKeys[key]*2+1]
+		if overlayIdx, ok := overlayKeys[key]; ok {
+			overlayValue := overlay.Content[overlayIdx*2+1]
+			if key == "steps" {
+				if overlayValue.Tag == removeTag {
+					// the whole `steps` key is being removed - don't
+					// fall through to mergeSteps, which doesn't know
+					// about !remove at the sequence level
+					continue
+				}
+				merged, err := mergeSteps(baseValue, overlayValue)
+				if err != nil {
+					return nil, err
+				}
+				appendIfPresent(result, key, merged)
+				continue
+			}
+			merged, err := mergeNodes(baseValue, overlayValue)
+			if err != nil {
+				return nil, err
+			}
+			appendIfPresent(result, key, merged)
+			continue
+		}
+		appendIfPresent(result, key, baseValue)
+	}
+
+	// any overlay-only keys are added as new entries
+	for _, key := range mappingKeyOrder(overlay) {
+		if _, ok := baseKeys[key]; ok {
+			continue
+		}
+		overlayValue := overlay.Content[overlayKeys[key]*2+1]
+		appendIfPresent(result, key, overlayValue)
+	}
+
+	// a `delete:` key is a terser alternative to `!remove`: it lists the
+	// keys to remove, either as a sequence of names or as a mapping of
+	// names to explicit nulls
+	if deleteIdx, ok := overlayKeys["delete"]; ok {
+		deleteNode := overlay.Content[deleteIdx*2+1]
+		result = applyDeleteList(result, deleteNode)
+	}
+
+	return result, nil
+}
+
+// mergeSteps merges an overlay `steps` sequence on top of the base
+// `steps` sequence, matching entries by their `name:` field instead of
+// position so an overlay can patch a single step without rewriting the
+// rest of the list.
+func mergeSteps(base, overlay *yaml.Node) (*yaml.Node, error) {
+	if base.Kind != yaml.SequenceNode || overlay.Kind != yaml.SequenceNode {
+		return overlay, nil
+	}
+
+	overlayByName := make(map[string]*yaml.Node, len(overlay.Content))
+	var overlayOrder []string
+	var unnamed []*yaml.Node
+	for _, step := range overlay.Content {
+		name, ok := stepName(step)
+		if !ok {
+			// a step with no `name:` can't be matched against the base,
+			// so treat it as a pure addition rather than silently
+			// dropping it
+			unnamed = append(unnamed, step)
+			continue
+		}
+		overlayByName[name] = step
+		overlayOrder = append(overlayOrder, name)
+	}
+
+	result := &yaml.Node{
+		Kind:  yaml.SequenceNode,
+		Tag:   base.Tag,
+		Style: base.Style,
+	}
+	seen := make(map[string]bool, len(overlayByName))
+	for _, step := range base.Content {
+		name, ok := stepName(step)
+		if !ok {
+			result.Content = append(result.Content, step)
+			continue
+		}
+		overlayStep, ok := overlayByName[name]
+		if !ok {
+			result.Content = append(result.Content, step)
+			continue
+		}
+		seen[name] = true
+		if overlayStep.Tag == removeTag {
+			continue
+		}
+		merged, err := mergeNodes(step, overlayStep)
+		if err != nil {
+			return nil, err
+		}
+		result.Content = append(result.Content, merged)
+	}
+
+	// overlay steps with no base counterpart are appended in overlay order
+	for _, name := range overlayOrder {
+		if seen[name] {
+			continue
+		}
+		overlayStep := overlayByName[name]
+		if overlayStep.Tag == removeTag {
+			continue
+		}
+		result.Content = append(result.Content, overlayStep)
+	}
+
+	result.Content = append(result.Content, unnamed...)
+
+	return result, nil
+}
+
+// stepName returns the `name:` field of a step mapping node, if present.
+func stepName(step *yaml.Node) (string, bool) {
+	if step.Kind != yaml.MappingNode {
+		return "", false
+	}
+	for i := 0; i+1 < len(step.Content); i += 2 {
+		if step.Content[i].Value == "name" {
+			return step.Content[i+1].Value, true
+		}
+	}
+	return "", false
+}
+
+// applyDeleteList removes the keys named in deleteNode (a sequence of
+// scalar key names, or a mapping whose keys with a null value are
+// removed) from result, and removes the `delete:` key itself.
+func applyDeleteList(result *yaml.Node, deleteNode *yaml.Node) *yaml.Node {
+	toDelete := make(map[string]bool)
+	switch deleteNode.Kind {
+	case yaml.SequenceNode:
+		for _, n := range deleteNode.Content {
+			toDelete[n.Value] = true
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(deleteNode.Content); i += 2 {
+			if deleteNode.Content[i+1].ShortTag() == "!!null" {
+				toDelete[deleteNode.Content[i].Value] = true
+			}
+		}
+	}
+	toDelete["delete"] = true
+
+	filtered := &yaml.Node{
+		Kind:  result.Kind,
+		Tag:   result.Tag,
+		Style: result.Style,
+	}
+	for i := 0; i+1 < len(result.Content); i += 2 {
+		if toDelete[result.Content[i].Value] {
+			continue
+		}
+		filtered.Content = append(filtered.Content, result.Content[i], result.Content[i+1])
+	}
+	return filtered
+}
+
+// appendIfPresent appends a key/value pair to a mapping node, unless
+// value is nil (meaning the key was removed via `!remove`).
+func appendIfPresent(mapping *yaml.Node, key string, value *yaml.Node) {
+	if value == nil {
+		return
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!str",
+		Value: key,
+	}, value)
+}
+
+// mappingKeyIndex returns a map from key name to its entry index (not
+// byte offset - multiply by 2 and add 1 to get the value's Content
+// index) within a mapping node.
+func mappingKeyIndex(mapping *yaml.Node) map[string]int {
+	index := make(map[string]int, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		index[mapping.Content[i].Value] = i / 2
+	}
+	return index
+}
+
+// mappingKeyOrder returns the keys of a mapping node in document order.
+func mappingKeyOrder(mapping *yaml.Node) []string {
+	keys := make([]string, 0, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keys = append(keys, mapping.Content[i].Value)
+	}
+	return keys
+}
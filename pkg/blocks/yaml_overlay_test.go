@@ -0,0 +1,151 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package blocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeTTPYAML(t *testing.T) {
+
+	testCases := []struct {
+		name    string
+		base    string
+		overlay string
+		want    string
+	}{
+		{
+			name: "Scalar Override",
+			base: `name: foo
+work_dir: /orig`,
+			overlay: `work_dir: /local`,
+			want: `name: foo
+work_dir: /local`,
+		},
+		{
+			name: "Map Merge Adds New Key",
+			base: `name: foo
+env:
+  FOO: bar`,
+			overlay: `env:
+  BAZ: qux`,
+			want: `name: foo
+env:
+  FOO: bar
+  BAZ: qux`,
+		},
+		{
+			name: "Remove Tag Deletes Key",
+			base: `name: foo
+env:
+  FOO: bar
+  BAZ: qux`,
+			overlay: `env:
+  BAZ: !remove`,
+			want: `name: foo
+env:
+  FOO: bar`,
+		},
+		{
+			name: "Delete Key List",
+			base: `name: foo
+env:
+  FOO: bar
+  BAZ: qux`,
+			overlay: `env:
+  delete: [BAZ]`,
+			want: `name: foo
+env:
+  FOO: bar`,
+		},
+		{
+			name: "Steps Patched By Name, Not Index",
+			base: `name: foo
+steps:
+  - name: first
+    inline: echo one
+  - name: second
+    inline: echo two`,
+			overlay: `steps:
+  - name: second
+    inline: echo patched`,
+			want: `name: foo
+steps:
+  - name: first
+    inline: echo one
+  - name: second
+    inline: echo patched`,
+		},
+		{
+			name: "Unnamed Overlay Step Is Appended, Not Dropped",
+			base: `name: foo
+steps:
+  - name: first
+    inline: echo one`,
+			overlay: `steps:
+  - inline: echo appended`,
+			want: `name: foo
+steps:
+  - name: first
+    inline: echo one
+  - inline: echo appended`,
+		},
+		{
+			name: "Remove Tag On Whole Steps Key",
+			base: `name: foo
+steps:
+  - name: first
+    inline: echo one`,
+			overlay: `steps: !remove`,
+			want:    `name: foo`,
+		},
+		{
+			name: "Remove Tag On Single Step By Name",
+			base: `name: foo
+steps:
+  - name: first
+    inline: echo one
+  - name: second
+    inline: echo two`,
+			overlay: `steps:
+  - !remove
+    name: first`,
+			want: `name: foo
+steps:
+  - name: second
+    inline: echo two`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := MergeTTPYAML([]byte(tc.base), []byte(tc.overlay))
+			require.NoError(t, err)
+
+			var got, want map[string]interface{}
+			require.NoError(t, yaml.Unmarshal(merged, &got))
+			require.NoError(t, yaml.Unmarshal([]byte(tc.want), &want))
+			assert.Equal(t, want, got)
+		})
+	}
+}
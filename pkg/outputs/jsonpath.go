@@ -0,0 +1,123 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/itchyny/gojq"
+)
+
+// JSONPathFilter extracts a value from JSON input using a JSONPath
+// expression, e.g. `$.items[0].id` or `$.items[?(@.state=="open")].id`.
+// Unlike JSONFilter's dotted paths, JSONPath can index arrays and apply
+// predicates.
+type JSONPathFilter struct {
+	Expression string `yaml:"jsonpath,omitempty"`
+}
+
+// Apply implements Filter for JSONPathFilter.
+func (f *JSONPathFilter) Apply(input string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return "", fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	result, err := jsonpath.Get(f.Expression, data)
+	if err != nil {
+		return "", fmt.Errorf("invalid jsonpath expression %q: %w", f.Expression, err)
+	}
+
+	return stringify(result)
+}
+
+// JQFilter extracts or transforms a value from JSON input using a jq
+// program, e.g. `.items[] | select(.state=="open") | .id`.
+type JQFilter struct {
+	Expression string `yaml:"jq,omitempty"`
+
+	query *gojq.Query
+}
+
+// NewJQFilter compiles expression into a JQFilter, so that callers
+// constructing filters programmatically can catch parse errors
+// immediately rather than on first Apply.
+//
+// **Parameters:**
+//
+// expression: The jq program to run against the filter's input.
+//
+// **Returns:**
+//
+// *JQFilter: The compiled filter.
+// error: An error if expression fails to parse.
+func NewJQFilter(expression string) (*JQFilter, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression %q: %w", expression, err)
+	}
+	return &JQFilter{Expression: expression, query: query}, nil
+}
+
+// Apply implements Filter for JQFilter.
+func (f *JQFilter) Apply(input string) (string, error) {
+	query := f.query
+	if query == nil {
+		var err error
+		query, err = gojq.Parse(f.Expression)
+		if err != nil {
+			return "", fmt.Errorf("invalid jq expression %q: %w", f.Expression, err)
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return "", fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	iter := query.Run(data)
+	var results []interface{}
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := value.(error); ok {
+			return "", fmt.Errorf("jq expression %q failed: %w", f.Expression, err)
+		}
+		results = append(results, value)
+	}
+
+	switch len(results) {
+	case 0:
+		return "", fmt.Errorf("jq expression %q produced no output", f.Expression)
+	case 1:
+		return stringify(results[0])
+	default:
+		// the program yielded more than one result (e.g. `.items[] |
+		// select(...)` matching multiple entries) - collect them into a
+		// JSON array rather than silently dropping all but the first,
+		// the same shape JSONPathFilter already produces for a
+		// multi-match predicate.
+		return stringify(results)
+	}
+}
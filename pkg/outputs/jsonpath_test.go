@@ -0,0 +1,145 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package outputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONPathFilter(t *testing.T) {
+
+	testCases := []struct {
+		name           string
+		input          string
+		spec           string
+		result         string
+		wantApplyError bool
+	}{
+		{
+			name:  "Array Index",
+			input: `{"items":[{"id":1},{"id":2}]}`,
+			spec: `name: first
+filters:
+  - jsonpath: $.items[0].id`,
+			result:         "1",
+			wantApplyError: false,
+		},
+		{
+			name:  "Predicate",
+			input: `{"items":[{"id":1,"state":"open"},{"id":2,"state":"closed"}]}`,
+			spec: `name: open
+filters:
+  - jsonpath: $.items[?(@.state=="open")].id`,
+			result:         "[1]",
+			wantApplyError: false,
+		},
+		{
+			name:  "Invalid Expression",
+			input: `{"items":[]}`,
+			spec: `name: bad
+filters:
+  - jsonpath: $.items[`,
+			wantApplyError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec Spec
+			err := yaml.Unmarshal([]byte(tc.spec), &spec)
+			require.NoError(t, err)
+
+			result, err := spec.Apply(tc.input)
+			if tc.wantApplyError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestJQFilter(t *testing.T) {
+
+	testCases := []struct {
+		name           string
+		input          string
+		spec           string
+		result         string
+		wantApplyError bool
+	}{
+		{
+			name:  "Select And Project",
+			input: `{"items":[{"id":1,"state":"open"},{"id":2,"state":"closed"}]}`,
+			spec: `name: open
+filters:
+  - jq: '.items[] | select(.state=="open") | .id'`,
+			result:         "1",
+			wantApplyError: false,
+		},
+		{
+			name:  "Select And Project Multiple Matches",
+			input: `{"items":[{"id":1,"state":"open"},{"id":2,"state":"open"},{"id":3,"state":"closed"}]}`,
+			spec: `name: open
+filters:
+  - jq: '.items[] | select(.state=="open") | .id'`,
+			result:         "[1,2]",
+			wantApplyError: false,
+		},
+		{
+			name:  "Invalid Expression",
+			input: `{"foo":"bar"}`,
+			spec: `name: bad
+filters:
+  - jq: '.foo | '`,
+			wantApplyError: true,
+		},
+		{
+			name:  "Chained With Regex",
+			input: `{"items":[{"id":123,"state":"open"}]}`,
+			spec: `name: chained
+filters:
+  - jq: '.items[] | select(.state=="open") | .id'
+  - regex: '^[0-9]+$'`,
+			result:         "123",
+			wantApplyError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec Spec
+			err := yaml.Unmarshal([]byte(tc.spec), &spec)
+			require.NoError(t, err)
+
+			result, err := spec.Apply(tc.input)
+			if tc.wantApplyError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2023-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package outputs implements the `outputs:` block that TTP steps use to
+// extract named values from their stdout/results for use by later steps
+// or for reporting.
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Filter transforms or extracts a value from a step's output. A Spec
+// chains one or more Filters together, feeding each filter's result into
+// the next.
+type Filter interface {
+	// Apply runs the filter against input and returns the filtered result.
+	Apply(input string) (string, error)
+}
+
+// JSONFilter extracts a value from JSON input using a dotted path, e.g.
+// `foo.bar` to index `{"foo":{"bar": ...}}`.
+type JSONFilter struct {
+	Path string `yaml:"json,omitempty"`
+}
+
+// Apply implements Filter for JSONFilter.
+func (f *JSONFilter) Apply(input string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return "", fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+
+	for _, key := range strings.Split(f.Path, ".") {
+		if key == "" {
+			return "", fmt.Errorf("invalid JSON path %q", f.Path)
+		}
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("cannot index %q: %q is not an object", f.Path, key)
+		}
+		val, ok := m[key]
+		if !ok {
+			return "", fmt.Errorf("key %q not found in JSON output (path %q)", key, f.Path)
+		}
+		data = val
+	}
+
+	return stringify(data)
+}
+
+// RegexFilter extracts the first match of a regular expression from its
+// input.
+type RegexFilter struct {
+	Regex string `yaml:"regex,omitempty"`
+}
+
+// Apply implements Filter for RegexFilter.
+func (f *RegexFilter) Apply(input string) (string, error) {
+	re, err := regexp.Compile(f.Regex)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex %q: %w", f.Regex, err)
+	}
+	match := re.FindString(input)
+	if match == "" {
+		return "", fmt.Errorf("regex %q did not match output", f.Regex)
+	}
+	return match, nil
+}
+
+// stringify renders a decoded JSON value as the string an output filter
+// should produce: strings pass through unquoted, everything else is
+// re-encoded as JSON so it can be fed into the next filter in a chain.
+func stringify(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filtered value: %w", err)
+	}
+	return string(b), nil
+}
+
+// Spec is a single named output, produced by running a step's result
+// through a chain of Filters.
+//
+// **Attributes:**
+//
+// Name: The variable name this output is exposed as to later steps.
+// Filters: The chain of filters applied, in order, to produce the output.
+type Spec struct {
+	Name    string   `yaml:"name"`
+	Filters []Filter `yaml:"filters,omitempty"`
+}
+
+// filterKeys mirrors the YAML shape of a single `filters:` list entry,
+// used to determine which concrete Filter implementation a given entry
+// selects.
+type filterKeys struct {
+	JSON     string `yaml:"json"`
+	Regex    string `yaml:"regex"`
+	JSONPath string `yaml:"jsonpath"`
+	JQ       string `yaml:"jq"`
+}
+
+// UnmarshalYAML implements custom unmarshalling for Spec so that each
+// entry of its Filters field - a slice of the Filter interface - is
+// decoded into whichever concrete implementation matches the key
+// (json/regex/jsonpath/jq) present on that entry.
+func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		Name    string      `yaml:"name"`
+		Filters []yaml.Node `yaml:"filters"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	s.Name = raw.Name
+	s.Filters = nil
+	for _, filterNode := range raw.Filters {
+		var keys filterKeys
+		if err := filterNode.Decode(&keys); err != nil {
+			return fmt.Errorf("failed to parse filter: %w", err)
+		}
+
+		switch {
+		case keys.JQ != "":
+			// parse errors surface on first Apply, matching the other
+			// filter kinds, rather than here at unmarshal time
+			s.Filters = append(s.Filters, &JQFilter{Expression: keys.JQ})
+		case keys.JSONPath != "":
+			s.Filters = append(s.Filters, &JSONPathFilter{Expression: keys.JSONPath})
+		case keys.JSON != "":
+			s.Filters = append(s.Filters, &JSONFilter{Path: keys.JSON})
+		case keys.Regex != "":
+			s.Filters = append(s.Filters, &RegexFilter{Regex: keys.Regex})
+		default:
+			return fmt.Errorf("filter entry must set exactly one of json, regex, jsonpath, or jq")
+		}
+	}
+	return nil
+}
+
+// Apply runs input through every filter in the spec's chain, in order,
+// passing each filter's result as the next filter's input.
+//
+// **Parameters:**
+//
+// input: The value to filter, typically a step's captured stdout.
+//
+// **Returns:**
+//
+// string: The final filtered value.
+// error: An error if any filter in the chain fails.
+func (s *Spec) Apply(input string) (string, error) {
+	result := input
+	for i, filter := range s.Filters {
+		var err error
+		result, err = filter.Apply(result)
+		if err != nil {
+			return "", fmt.Errorf("filter %d of output %q failed: %w", i+1, s.Name, err)
+		}
+	}
+	return result, nil
+}
+
+// Parse applies each of specs to input and returns the resulting values
+// keyed by their Spec.Name, for use as template variables in later
+// steps.
+//
+// **Parameters:**
+//
+// specs: The output specs to apply, typically from a step's `outputs:` block.
+// input: The value to filter, typically a step's captured stdout.
+//
+// **Returns:**
+//
+// map[string]string: The named output values.
+// error: An error if any spec fails to apply.
+func Parse(specs []Spec, input string) (map[string]string, error) {
+	results := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		val, err := spec.Apply(input)
+		if err != nil {
+			return nil, err
+		}
+		results[spec.Name] = val
+	}
+	return results, nil
+}